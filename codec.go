@@ -0,0 +1,165 @@
+package easytcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DarthPestilane/easytcp/message"
+	"github.com/DarthPestilane/easytcp/packet"
+	gogoproto "github.com/gogo/protobuf/proto"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encodes and decodes message payloads. A Server holds a
+// CodecRegistry (see Server.Codecs), which lets a single server accept
+// and reply in several encodings at once, keyed by message.ContentType.
+type Codec interface {
+	Encode(v interface{}) ([]byte, error)
+	Decode(data []byte, v interface{}) error
+}
+
+// Content types for the codecs shipped in this package. Custom codecs
+// registered with a CodecRegistry should pick an unused byte.
+const (
+	ContentTypeJSON ContentType = iota
+	ContentTypeProtobuf
+	ContentTypeGogoproto
+	ContentTypeMsgpack
+)
+
+// ContentType is an alias of message.ContentType for convenience in
+// ServerOption and CodecRegistry call sites.
+type ContentType = message.ContentType
+
+// JsonCodec encodes/decodes using encoding/json.
+type JsonCodec struct{}
+
+func (c *JsonCodec) Encode(v interface{}) ([]byte, error) { return json.Marshal(v) }
+func (c *JsonCodec) Decode(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// ProtobufCodec encodes/decodes using google.golang.org/protobuf.
+type ProtobufCodec struct{}
+
+func (c *ProtobufCodec) Encode(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec: %T does not implement proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (c *ProtobufCodec) Decode(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf codec: %T does not implement proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// GogoprotoCodec encodes/decodes using github.com/gogo/protobuf, which
+// generates faster marshal/unmarshal code than the stdlib protobuf
+// implementation for servers that can afford the extra dependency.
+type GogoprotoCodec struct{}
+
+func (c *GogoprotoCodec) Encode(v interface{}) ([]byte, error) {
+	m, ok := v.(gogoproto.Message)
+	if !ok {
+		return nil, fmt.Errorf("gogoproto codec: %T does not implement proto.Message", v)
+	}
+	return gogoproto.Marshal(m)
+}
+
+func (c *GogoprotoCodec) Decode(data []byte, v interface{}) error {
+	m, ok := v.(gogoproto.Message)
+	if !ok {
+		return fmt.Errorf("gogoproto codec: %T does not implement proto.Message", v)
+	}
+	return gogoproto.Unmarshal(data, m)
+}
+
+// MsgpackCodec encodes/decodes using github.com/vmihailenco/msgpack.
+type MsgpackCodec struct{}
+
+func (c *MsgpackCodec) Encode(v interface{}) ([]byte, error) { return msgpack.Marshal(v) }
+func (c *MsgpackCodec) Decode(data []byte, v interface{}) error { return msgpack.Unmarshal(data, v) }
+
+// CodecRegistry maps a message.ContentType to the Codec that handles it,
+// so handler code can look up the right Codec per-message instead of
+// assuming a single server-wide encoding.
+type CodecRegistry struct {
+	codecs map[ContentType]Codec
+}
+
+// NewCodecRegistry creates a CodecRegistry pre-populated with the codecs
+// shipped in this package.
+func NewCodecRegistry() *CodecRegistry {
+	r := &CodecRegistry{codecs: make(map[ContentType]Codec)}
+	r.Register(ContentTypeJSON, &JsonCodec{})
+	r.Register(ContentTypeProtobuf, &ProtobufCodec{})
+	r.Register(ContentTypeGogoproto, &GogoprotoCodec{})
+	r.Register(ContentTypeMsgpack, &MsgpackCodec{})
+	return r
+}
+
+// Register associates ct with codec, overwriting any existing entry.
+func (r *CodecRegistry) Register(ct ContentType, codec Codec) {
+	r.codecs[ct] = codec
+}
+
+// Get returns the Codec registered for ct, or false if none is.
+func (r *CodecRegistry) Get(ct ContentType) (Codec, bool) {
+	c, ok := r.codecs[ct]
+	return c, ok
+}
+
+// Decode decodes entry.Data into v using the Codec registered for
+// entry.ContentType.
+func (r *CodecRegistry) Decode(entry *message.Entry, v interface{}) error {
+	c, ok := r.Get(entry.ContentType)
+	if !ok {
+		return fmt.Errorf("codec registry: no codec registered for content-type %d", entry.ContentType)
+	}
+	return c.Decode(entry.Data, v)
+}
+
+// Encode encodes v using the Codec registered for ct, and stamps the
+// resulting Entry with ct so the peer knows how to decode it.
+func (r *CodecRegistry) Encode(id int, ct ContentType, v interface{}) (*message.Entry, error) {
+	c, ok := r.Get(ct)
+	if !ok {
+		return nil, fmt.Errorf("codec registry: no codec registered for content-type %d", ct)
+	}
+	data, err := c.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return &message.Entry{ID: id, ContentType: ct, Data: data}, nil
+}
+
+// DecodeRequest decodes req.Data into v using the Codec registered for
+// req.ContentType. This is what handlers call to get a typed payload out
+// of a *packet.Request instead of handling req.Data themselves.
+func (r *CodecRegistry) DecodeRequest(req *packet.Request, v interface{}) error {
+	c, ok := r.Get(req.ContentType)
+	if !ok {
+		return fmt.Errorf("codec registry: no codec registered for content-type %d", req.ContentType)
+	}
+	return c.Decode(req.Data, v)
+}
+
+// EncodeResponse encodes v using the Codec registered for ct, and
+// returns a *packet.Response stamped with ct so the peer knows how to
+// decode it. This is what handlers call to build their return value
+// instead of encoding the payload themselves.
+func (r *CodecRegistry) EncodeResponse(id uint, ct ContentType, v interface{}) (*packet.Response, error) {
+	c, ok := r.Get(ct)
+	if !ok {
+		return nil, fmt.Errorf("codec registry: no codec registered for content-type %d", ct)
+	}
+	data, err := c.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return &packet.Response{ID: id, Data: data, ContentType: ct}, nil
+}
@@ -0,0 +1,80 @@
+package easytcp
+
+import (
+	"testing"
+
+	"github.com/DarthPestilane/easytcp/packet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonCodec_EncodeDecode(t *testing.T) {
+	c := &JsonCodec{}
+
+	data, err := c.Encode(map[string]string{"foo": "bar"})
+	require.NoError(t, err)
+
+	var got map[string]string
+	require.NoError(t, c.Decode(data, &got))
+	assert.Equal(t, map[string]string{"foo": "bar"}, got)
+}
+
+func TestMsgpackCodec_EncodeDecode(t *testing.T) {
+	c := &MsgpackCodec{}
+
+	data, err := c.Encode(map[string]string{"foo": "bar"})
+	require.NoError(t, err)
+
+	var got map[string]string
+	require.NoError(t, c.Decode(data, &got))
+	assert.Equal(t, map[string]string{"foo": "bar"}, got)
+}
+
+func TestNewCodecRegistry_HasDefaultCodecs(t *testing.T) {
+	r := NewCodecRegistry()
+
+	for _, ct := range []ContentType{ContentTypeJSON, ContentTypeProtobuf, ContentTypeGogoproto, ContentTypeMsgpack} {
+		_, ok := r.Get(ct)
+		assert.True(t, ok, "content-type %d should have a default codec", ct)
+	}
+}
+
+func TestCodecRegistry_DecodeRequest(t *testing.T) {
+	r := NewCodecRegistry()
+
+	data, err := (&JsonCodec{}).Encode(map[string]string{"foo": "bar"})
+	require.NoError(t, err)
+	req := &packet.Request{Data: data, ContentType: ContentTypeJSON}
+
+	var got map[string]string
+	require.NoError(t, r.DecodeRequest(req, &got))
+	assert.Equal(t, map[string]string{"foo": "bar"}, got)
+}
+
+func TestCodecRegistry_DecodeRequest_UnregisteredContentType(t *testing.T) {
+	r := NewCodecRegistry()
+	req := &packet.Request{ContentType: 99}
+
+	err := r.DecodeRequest(req, &map[string]string{})
+	assert.Error(t, err)
+}
+
+func TestCodecRegistry_EncodeResponse(t *testing.T) {
+	r := NewCodecRegistry()
+
+	resp, err := r.EncodeResponse(1, ContentTypeJSON, map[string]string{"foo": "bar"})
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), resp.ID)
+	assert.Equal(t, ContentTypeJSON, resp.ContentType)
+
+	var got map[string]string
+	require.NoError(t, (&JsonCodec{}).Decode(resp.Data, &got))
+	assert.Equal(t, map[string]string{"foo": "bar"}, got)
+}
+
+func TestCodecRegistry_EncodeResponse_UnregisteredContentType(t *testing.T) {
+	r := NewCodecRegistry()
+
+	_, err := r.EncodeResponse(1, 99, map[string]string{})
+	assert.Error(t, err)
+}
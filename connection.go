@@ -0,0 +1,144 @@
+package easytcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DarthPestilane/easytcp/metrics"
+	"github.com/DarthPestilane/easytcp/packet"
+)
+
+// connSeq assigns each Connection a process-unique ID, since net.Conn
+// doesn't expose one of its own.
+var connSeq int64
+
+// Connection wraps a net.Conn accepted by a Server and adapts it to
+// session.Session, so a router.Router can route requests read off it
+// and send responses back without knowing about net.Conn at all.
+type Connection struct {
+	id     string
+	conn   net.Conn
+	srv    *Server
+	packer packet.Packer
+
+	reqCh chan *packet.Request
+
+	writeMu sync.Mutex
+
+	// Closed is closed once the connection has stopped reading and
+	// writing.
+	Closed chan struct{}
+
+	closeOnce sync.Once
+}
+
+// NewConnection wraps conn for use by srv.
+func NewConnection(conn net.Conn, srv *Server) *Connection {
+	id := conn.RemoteAddr().String() + "#" + strconv.FormatInt(atomic.AddInt64(&connSeq, 1), 10)
+	return &Connection{
+		id:     id,
+		conn:   conn,
+		srv:    srv,
+		packer: srv.packer,
+		reqCh:  make(chan *packet.Request),
+		Closed: make(chan struct{}),
+	}
+}
+
+// ID implements session.Session.
+func (c *Connection) ID() string { return c.id }
+
+// RecvReq implements session.Session. The channel is closed once the
+// connection stops reading, either because the peer hung up or ctx
+// passed to KeepReading was canceled.
+func (c *Connection) RecvReq() <-chan *packet.Request { return c.reqCh }
+
+// SendResp implements session.Session.
+func (c *Connection) SendResp(resp *packet.Response) (int, error) {
+	frame, err := c.packer.Pack(resp)
+	if err != nil {
+		return 0, err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	n, err := c.conn.Write(frame)
+	if n > 0 {
+		metrics.BytesOut.Add(float64(n))
+	}
+	return n, err
+}
+
+// KeepReading unpacks frames off the connection and delivers them on
+// RecvReq until a read/unpack error occurs or the connection is closed.
+// It honors ctx: once ctx is done, any in-flight blocking Read is
+// interrupted via a read deadline instead of waiting indefinitely for
+// the peer to hang up, so Server.Shutdown's drain deadline is real.
+// Conns from UDPTransport ignore read deadlines, so for them the Read
+// only returns once the conn is closed.
+func (c *Connection) KeepReading(ctx context.Context) {
+	defer c.close()
+
+	stopWatch := make(chan struct{})
+	defer close(stopWatch)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.conn.SetReadDeadline(time.Now())
+		case <-stopWatch:
+		}
+	}()
+
+	counting := &countingReader{r: c.conn}
+	for {
+		req, err := c.packer.Unpack(counting)
+		if err != nil {
+			var netErr net.Error
+			isCleanClose := errors.Is(err, io.EOF) || errors.As(err, &netErr)
+			if !isCleanClose {
+				metrics.UnpackErrors.Inc()
+			}
+			return
+		}
+		select {
+		case c.reqCh <- req:
+		case <-c.Closed:
+			return
+		}
+	}
+}
+
+// Close closes the underlying net.Conn, signals Closed and closes the
+// request channel, if it hasn't already.
+func (c *Connection) Close() error {
+	c.closeOnce.Do(func() {
+		_ = c.conn.Close()
+		close(c.reqCh)
+		close(c.Closed)
+	})
+	return nil
+}
+
+func (c *Connection) close() {
+	_ = c.Close()
+}
+
+// countingReader wraps an io.Reader, recording every byte read as
+// metrics.BytesIn, regardless of how the Packer chooses to frame reads
+// off of it.
+type countingReader struct {
+	r io.Reader
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	if n > 0 {
+		metrics.BytesIn.Add(float64(n))
+	}
+	return n, err
+}
@@ -0,0 +1,24 @@
+package easytcp
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DiagnosticsHandler returns an http.Handler serving Prometheus metrics
+// at /metrics and net/http/pprof profiles under /debug/pprof/, so
+// operators get a peer/traffic diagnostics view without rebuilding it
+// themselves. Mount it on an internal-only address; it's not meant to
+// be exposed alongside the TCP listener.
+func (s *Server) DiagnosticsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
@@ -0,0 +1,101 @@
+// Package heartbeat is a built-in keepalive subsystem: a per-session
+// ticker sending a ping packet.Response, last-received-frame tracking, and
+// eviction of sessions that go quiet - including half-open TCP
+// connections behind NAT that never see a TCP RST.
+package heartbeat
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/DarthPestilane/easytcp/packet"
+	"github.com/DarthPestilane/easytcp/router"
+	"github.com/DarthPestilane/easytcp/session"
+)
+
+// Config configures the heartbeat subsystem.
+type Config struct {
+	// Interval is how often a ping is sent to a session.
+	Interval time.Duration
+
+	// Timeout is how long a session may go without a received frame
+	// before Run evicts it.
+	Timeout time.Duration
+
+	// PingID is the route ID used for outgoing pings.
+	PingID uint
+
+	// PongID is the route ID peers reply with. Requests with this ID
+	// are swallowed by Middleware rather than reaching the handler
+	// stack.
+	PongID uint
+}
+
+// Tracker records the last time a frame was received from each session
+// and drives the per-session ping/eviction loop.
+type Tracker struct {
+	cfg  Config
+	last sync.Map // session id -> int64 (unix nanos)
+}
+
+// NewTracker creates a Tracker for cfg.
+func NewTracker(cfg Config) *Tracker {
+	return &Tracker{cfg: cfg}
+}
+
+// Middleware returns a router.MiddlewareFunc that stamps the session's
+// last-received-frame time on every request, so Run's eviction check
+// reflects real traffic, not just pongs. Compose it ahead of other
+// middlewares so the timestamp updates even if a later one returns an
+// error.
+func (t *Tracker) Middleware() router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(s session.Session, req *packet.Request) (*packet.Response, error) {
+			t.touch(s.ID())
+			if req.ID == t.cfg.PongID {
+				return nil, nil
+			}
+			return next(s, req)
+		}
+	}
+}
+
+// Run sends s a ping every Interval and returns once s has gone
+// Timeout without a received frame, a ping fails to send, or ctx is
+// done. Callers should close s when Run returns due to a timeout or a
+// failed ping; it returns nil in those cases and ctx.Err() when ctx
+// ended Run instead.
+func (t *Tracker) Run(ctx context.Context, s session.Session) error {
+	t.touch(s.ID())
+	defer t.last.Delete(s.ID())
+
+	ticker := time.NewTicker(t.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if t.idleFor(s.ID()) > t.cfg.Timeout {
+				return nil
+			}
+			if _, err := s.SendResp(&packet.Response{ID: t.cfg.PingID}); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func (t *Tracker) touch(id string) {
+	t.last.Store(id, time.Now().UnixNano())
+}
+
+func (t *Tracker) idleFor(id string) time.Duration {
+	v, ok := t.last.Load(id)
+	if !ok {
+		return 0
+	}
+	return time.Since(time.Unix(0, v.(int64)))
+}
@@ -0,0 +1,152 @@
+package heartbeat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DarthPestilane/easytcp/packet"
+	"github.com/DarthPestilane/easytcp/router"
+	"github.com/DarthPestilane/easytcp/session"
+	"github.com/DarthPestilane/easytcp/session/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_Middleware_SwallowsPong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mock.NewMockSession(ctrl)
+	sess.EXPECT().ID().AnyTimes().Return("sess id")
+
+	tr := NewTracker(Config{PongID: 2})
+
+	var called bool
+	var next router.HandlerFunc = func(s session.Session, req *packet.Request) (*packet.Response, error) {
+		called = true
+		return nil, nil
+	}
+	wrapped := tr.Middleware()(next)
+
+	_, err := wrapped(sess, &packet.Request{ID: 2})
+	require.NoError(t, err)
+	assert.False(t, called, "middleware should swallow a pong request instead of calling next")
+}
+
+func TestTracker_Middleware_PassesThroughOtherRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mock.NewMockSession(ctrl)
+	sess.EXPECT().ID().AnyTimes().Return("sess id")
+
+	tr := NewTracker(Config{PongID: 2})
+
+	var called bool
+	var next router.HandlerFunc = func(s session.Session, req *packet.Request) (*packet.Response, error) {
+		called = true
+		return nil, nil
+	}
+	wrapped := tr.Middleware()(next)
+
+	_, err := wrapped(sess, &packet.Request{ID: 1})
+	require.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestTracker_Run_EvictsAfterTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mock.NewMockSession(ctrl)
+	sess.EXPECT().ID().AnyTimes().Return("sess id")
+	sess.EXPECT().SendResp(gomock.Any()).AnyTimes().Return(0, nil)
+
+	tr := NewTracker(Config{
+		Interval: 5 * time.Millisecond,
+		Timeout:  20 * time.Millisecond,
+		PingID:   1,
+	})
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- tr.Run(context.Background(), sess) }()
+
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err, "Run should return nil when it evicts on timeout")
+	case <-time.After(time.Second):
+		t.Fatal("Run never returned")
+	}
+}
+
+func TestTracker_Run_StopsOnContextCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mock.NewMockSession(ctrl)
+	sess.EXPECT().ID().AnyTimes().Return("sess id")
+	sess.EXPECT().SendResp(gomock.Any()).AnyTimes().Return(0, nil)
+
+	tr := NewTracker(Config{
+		Interval: time.Hour,
+		Timeout:  time.Hour,
+		PingID:   1,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- tr.Run(ctx, sess) }()
+	cancel()
+
+	select {
+	case err := <-errCh:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("Run never returned after ctx was canceled")
+	}
+}
+
+func TestTracker_Run_TouchedSessionDoesNotTimeOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mock.NewMockSession(ctrl)
+	sess.EXPECT().ID().AnyTimes().Return("sess id")
+	sess.EXPECT().SendResp(gomock.Any()).AnyTimes().Return(0, nil)
+
+	tr := NewTracker(Config{
+		Interval: 5 * time.Millisecond,
+		Timeout:  30 * time.Millisecond,
+		PingID:   1,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() { errCh <- tr.Run(ctx, sess) }()
+
+	// Keep "receiving frames" on the session faster than Timeout, so Run
+	// shouldn't evict it on its own.
+	stop := time.After(60 * time.Millisecond)
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			tr.touch(sess.ID())
+		case <-stop:
+			break loop
+		}
+	}
+
+	select {
+	case err := <-errCh:
+		t.Fatalf("Run returned early (err=%v) despite the session being kept alive", err)
+	default:
+	}
+	cancel()
+	<-errCh
+}
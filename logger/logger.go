@@ -0,0 +1,25 @@
+// Package logger defines the minimal logging interface used across easytcp,
+// so the rest of the library isn't hard-wired to a specific logging backend.
+package logger
+
+// Fields is a set of key/value pairs attached to a log line.
+type Fields map[string]interface{}
+
+// Logger is the logging interface easytcp depends on internally. Adapters
+// for logrus, zap, zerolog and the stdlib log/slog package are provided in
+// this package; implement it directly to plug in anything else.
+type Logger interface {
+	Tracef(format string, args ...interface{})
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+
+	// With returns a Logger that annotates every line it logs with fields.
+	With(fields Fields) Logger
+}
+
+// Default is the Logger used by easytcp when ServerOption.Logger isn't set.
+// It's backed by logrus.StandardLogger() to preserve the library's previous
+// behaviour.
+var Default Logger = NewLogrus(nil)
@@ -0,0 +1,26 @@
+package logger
+
+import "github.com/sirupsen/logrus"
+
+// logrusLogger adapts a *logrus.Entry to the Logger interface.
+type logrusLogger struct {
+	entry *logrus.Entry
+}
+
+// NewLogrus wraps l as a Logger. A nil l uses logrus.StandardLogger().
+func NewLogrus(l *logrus.Logger) Logger {
+	if l == nil {
+		l = logrus.StandardLogger()
+	}
+	return &logrusLogger{entry: logrus.NewEntry(l)}
+}
+
+func (a *logrusLogger) Tracef(format string, args ...interface{}) { a.entry.Tracef(format, args...) }
+func (a *logrusLogger) Debugf(format string, args ...interface{}) { a.entry.Debugf(format, args...) }
+func (a *logrusLogger) Infof(format string, args ...interface{})  { a.entry.Infof(format, args...) }
+func (a *logrusLogger) Warnf(format string, args ...interface{})  { a.entry.Warnf(format, args...) }
+func (a *logrusLogger) Errorf(format string, args ...interface{}) { a.entry.Errorf(format, args...) }
+
+func (a *logrusLogger) With(fields Fields) Logger {
+	return &logrusLogger{entry: a.entry.WithFields(logrus.Fields(fields))}
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// slogLogger adapts a *slog.Logger to the Logger interface. slog has no
+// Trace level; Tracef logs at Debug.
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlog wraps l as a Logger.
+func NewSlog(l *slog.Logger) Logger {
+	return &slogLogger{l: l}
+}
+
+func (a *slogLogger) Tracef(format string, args ...interface{}) {
+	a.l.Log(context.Background(), slog.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (a *slogLogger) Debugf(format string, args ...interface{}) {
+	a.l.Debug(fmt.Sprintf(format, args...))
+}
+
+func (a *slogLogger) Infof(format string, args ...interface{}) {
+	a.l.Info(fmt.Sprintf(format, args...))
+}
+
+func (a *slogLogger) Warnf(format string, args ...interface{}) {
+	a.l.Warn(fmt.Sprintf(format, args...))
+}
+
+func (a *slogLogger) Errorf(format string, args ...interface{}) {
+	a.l.Error(fmt.Sprintf(format, args...))
+}
+
+func (a *slogLogger) With(fields Fields) Logger {
+	args := make([]interface{}, 0, len(fields)*2)
+	for k, v := range fields {
+		args = append(args, k, v)
+	}
+	return &slogLogger{l: a.l.With(args...)}
+}
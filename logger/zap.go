@@ -0,0 +1,27 @@
+package logger
+
+import "go.uber.org/zap"
+
+// zapLogger adapts a *zap.SugaredLogger to the Logger interface.
+type zapLogger struct {
+	sugar *zap.SugaredLogger
+}
+
+// NewZap wraps l as a Logger. zap has no Trace level; Tracef logs at Debug.
+func NewZap(l *zap.Logger) Logger {
+	return &zapLogger{sugar: l.Sugar()}
+}
+
+func (a *zapLogger) Tracef(format string, args ...interface{}) { a.sugar.Debugf(format, args...) }
+func (a *zapLogger) Debugf(format string, args ...interface{}) { a.sugar.Debugf(format, args...) }
+func (a *zapLogger) Infof(format string, args ...interface{})  { a.sugar.Infof(format, args...) }
+func (a *zapLogger) Warnf(format string, args ...interface{})  { a.sugar.Warnf(format, args...) }
+func (a *zapLogger) Errorf(format string, args ...interface{}) { a.sugar.Errorf(format, args...) }
+
+func (a *zapLogger) With(fields Fields) Logger {
+	args := make([]interface{}, 0, len(fields)*2)
+	for k, v := range fields {
+		args = append(args, k, v)
+	}
+	return &zapLogger{sugar: a.sugar.With(args...)}
+}
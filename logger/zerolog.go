@@ -0,0 +1,27 @@
+package logger
+
+import "github.com/rs/zerolog"
+
+// zerologLogger adapts a zerolog.Logger to the Logger interface.
+type zerologLogger struct {
+	l zerolog.Logger
+}
+
+// NewZerolog wraps l as a Logger.
+func NewZerolog(l zerolog.Logger) Logger {
+	return &zerologLogger{l: l}
+}
+
+func (a *zerologLogger) Tracef(format string, args ...interface{}) { a.l.Trace().Msgf(format, args...) }
+func (a *zerologLogger) Debugf(format string, args ...interface{}) { a.l.Debug().Msgf(format, args...) }
+func (a *zerologLogger) Infof(format string, args ...interface{})  { a.l.Info().Msgf(format, args...) }
+func (a *zerologLogger) Warnf(format string, args ...interface{})  { a.l.Warn().Msgf(format, args...) }
+func (a *zerologLogger) Errorf(format string, args ...interface{}) { a.l.Error().Msgf(format, args...) }
+
+func (a *zerologLogger) With(fields Fields) Logger {
+	ctx := a.l.With()
+	for k, v := range fields {
+		ctx = ctx.Interface(k, v)
+	}
+	return &zerologLogger{l: ctx.Logger()}
+}
@@ -0,0 +1,16 @@
+// Package message defines the wire-level envelope easytcp routes and
+// dispatches, independent of how it was packed or encoded on the wire.
+package message
+
+// ContentType identifies which Codec encoded an Entry's Data, so a
+// single server can accept mixed encodings on the same port and reply
+// in whatever encoding the peer used.
+type ContentType byte
+
+// Entry is one routed message: an ID used to look up a handler, the
+// encoded payload, and the ContentType needed to decode it.
+type Entry struct {
+	ID          int
+	ContentType ContentType
+	Data        []byte
+}
@@ -0,0 +1,99 @@
+// Package metrics exposes Prometheus instrumentation for easytcp servers:
+// active sessions, bytes in/out, messages by route, handler latency,
+// unpack errors, and middleware-observed panics.
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/DarthPestilane/easytcp/packet"
+	"github.com/DarthPestilane/easytcp/router"
+	"github.com/DarthPestilane/easytcp/session"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics collected across all servers in the process. They're package
+// vars, like the rest of the client_golang ecosystem, so they survive
+// Server restarts and can be scraped from a single /metrics endpoint.
+var (
+	ActiveSessions = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "easytcp",
+		Name:      "active_sessions",
+		Help:      "Number of currently connected sessions.",
+	})
+
+	BytesIn = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "easytcp",
+		Name:      "bytes_in_total",
+		Help:      "Total bytes read from peers.",
+	})
+
+	BytesOut = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "easytcp",
+		Name:      "bytes_out_total",
+		Help:      "Total bytes written to peers.",
+	})
+
+	MessagesByRoute = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "easytcp",
+		Name:      "messages_total",
+		Help:      "Number of messages routed, by route ID.",
+	}, []string{"route"})
+
+	HandlerLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "easytcp",
+		Name:      "handler_latency_seconds",
+		Help:      "Handler execution latency, by route ID.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route"})
+
+	UnpackErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "easytcp",
+		Name:      "unpack_errors_total",
+		Help:      "Number of frames that failed to unpack.",
+	})
+
+	MiddlewarePanics = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "easytcp",
+		Name:      "middleware_panics_total",
+		Help:      "Number of panics recovered while running the handler stack.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(
+		ActiveSessions,
+		BytesIn,
+		BytesOut,
+		MessagesByRoute,
+		HandlerLatency,
+		UnpackErrors,
+		MiddlewarePanics,
+	)
+}
+
+// Middleware returns a router.MiddlewareFunc that records per-route
+// message counts and handler latency, and turns a panic inside the
+// handler stack into a counted, returned error instead of crashing the
+// Executor worker goroutine running it.
+func Middleware() router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(s session.Session, req *packet.Request) (resp *packet.Response, err error) {
+			route := strconv.FormatUint(uint64(req.ID), 10)
+			MessagesByRoute.WithLabelValues(route).Inc()
+
+			start := time.Now()
+			defer func() {
+				HandlerLatency.WithLabelValues(route).Observe(time.Since(start).Seconds())
+				if r := recover(); r != nil {
+					MiddlewarePanics.Inc()
+					err = fmt.Errorf("panic in handler: %v", r)
+				}
+			}()
+
+			return next(s, req)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DarthPestilane/easytcp/packet"
+	"github.com/DarthPestilane/easytcp/router"
+	"github.com/DarthPestilane/easytcp/session"
+	"github.com/DarthPestilane/easytcp/session/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_RecordsMessageByRoute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mock.NewMockSession(ctrl)
+	sess.EXPECT().ID().AnyTimes().Return("sess id")
+
+	before := testutil.ToFloat64(MessagesByRoute.WithLabelValues("7"))
+
+	var next router.HandlerFunc = func(s session.Session, req *packet.Request) (*packet.Response, error) {
+		return nil, nil
+	}
+	wrapped := Middleware()(next)
+
+	_, err := wrapped(sess, &packet.Request{ID: 7})
+	require.NoError(t, err)
+
+	after := testutil.ToFloat64(MessagesByRoute.WithLabelValues("7"))
+	assert.Equal(t, before+1, after)
+}
+
+func TestMiddleware_RecoversPanicAndCountsIt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mock.NewMockSession(ctrl)
+	sess.EXPECT().ID().AnyTimes().Return("sess id")
+
+	before := testutil.ToFloat64(MiddlewarePanics)
+
+	var next router.HandlerFunc = func(s session.Session, req *packet.Request) (*packet.Response, error) {
+		panic("boom")
+	}
+	wrapped := Middleware()(next)
+
+	resp, err := wrapped(sess, &packet.Request{ID: 9})
+	assert.Nil(t, resp)
+	assert.Error(t, err)
+
+	after := testutil.ToFloat64(MiddlewarePanics)
+	assert.Equal(t, before+1, after)
+}
+
+func TestMiddleware_PropagatesHandlerError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mock.NewMockSession(ctrl)
+	sess.EXPECT().ID().AnyTimes().Return("sess id")
+
+	wantErr := errors.New("handler failed")
+	var next router.HandlerFunc = func(s session.Session, req *packet.Request) (*packet.Response, error) {
+		return nil, wantErr
+	}
+	wrapped := Middleware()(next)
+
+	_, err := wrapped(sess, &packet.Request{ID: 3})
+	assert.Equal(t, wantErr, err)
+}
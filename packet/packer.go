@@ -0,0 +1,57 @@
+package packet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/DarthPestilane/easytcp/message"
+)
+
+// Packer frames Request/Response values onto the wire so a stream
+// transport (TCP, TLS) knows where one message ends and the next
+// begins.
+type Packer interface {
+	// Pack encodes resp into a length-prefixed frame ready to write.
+	Pack(resp *Response) ([]byte, error)
+
+	// Unpack reads the next frame off r and decodes it into a Request.
+	Unpack(r io.Reader) (*Request, error)
+}
+
+// maxFrameSize bounds the size field read off the wire, so a corrupt or
+// hostile peer can't make Unpack allocate an unbounded buffer.
+const maxFrameSize = 1 << 22 // 4MiB
+
+// DefaultPacker frames a message as a 4-byte big-endian ID, a 1-byte
+// content-type, a 4-byte big-endian payload length, then the payload.
+type DefaultPacker struct{}
+
+// Pack implements Packer.
+func (p *DefaultPacker) Pack(resp *Response) ([]byte, error) {
+	buf := make([]byte, 9+len(resp.Data))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(resp.ID))
+	buf[4] = byte(resp.ContentType)
+	binary.BigEndian.PutUint32(buf[5:9], uint32(len(resp.Data)))
+	copy(buf[9:], resp.Data)
+	return buf, nil
+}
+
+// Unpack implements Packer.
+func (p *DefaultPacker) Unpack(r io.Reader) (*Request, error) {
+	var head [9]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+	id := binary.BigEndian.Uint32(head[0:4])
+	ct := head[4]
+	size := binary.BigEndian.Uint32(head[5:9])
+	if size > maxFrameSize {
+		return nil, fmt.Errorf("packet: frame size %d exceeds max %d", size, maxFrameSize)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return &Request{ID: uint(id), Data: data, ContentType: message.ContentType(ct)}, nil
+}
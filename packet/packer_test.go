@@ -0,0 +1,61 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DarthPestilane/easytcp/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultPacker_PackUnpack(t *testing.T) {
+	p := &DefaultPacker{}
+
+	resp := &Response{ID: 42, Data: []byte("hello")}
+	frame, err := p.Pack(resp)
+	assert.NoError(t, err)
+
+	req, err := p.Unpack(bytes.NewReader(frame))
+	assert.NoError(t, err)
+	assert.Equal(t, uint(42), req.ID)
+	assert.Equal(t, []byte("hello"), req.Data)
+}
+
+func TestDefaultPacker_PackUnpack_PreservesContentType(t *testing.T) {
+	p := &DefaultPacker{}
+
+	resp := &Response{ID: 42, Data: []byte("hello"), ContentType: 3}
+	frame, err := p.Pack(resp)
+	assert.NoError(t, err)
+
+	req, err := p.Unpack(bytes.NewReader(frame))
+	assert.NoError(t, err)
+	assert.Equal(t, message.ContentType(3), req.ContentType)
+}
+
+func TestDefaultPacker_Unpack_EmptyPayload(t *testing.T) {
+	p := &DefaultPacker{}
+
+	frame, err := p.Pack(&Response{ID: 1})
+	assert.NoError(t, err)
+
+	req, err := p.Unpack(bytes.NewReader(frame))
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), req.ID)
+	assert.Empty(t, req.Data)
+}
+
+func TestDefaultPacker_Unpack_OversizedFrameRejected(t *testing.T) {
+	p := &DefaultPacker{}
+
+	head := make([]byte, 9)
+	head[5] = 0xff // size = 0xff000000, well past maxFrameSize
+	_, err := p.Unpack(bytes.NewReader(head))
+	assert.Error(t, err)
+}
+
+func TestDefaultPacker_Unpack_ShortRead(t *testing.T) {
+	p := &DefaultPacker{}
+	_, err := p.Unpack(bytes.NewReader([]byte{0, 0}))
+	assert.Error(t, err)
+}
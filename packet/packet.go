@@ -0,0 +1,26 @@
+// Package packet defines the request/response shapes router.Router and
+// session.Session exchange once a raw frame has been unpacked.
+package packet
+
+import "github.com/DarthPestilane/easytcp/message"
+
+// Request is an incoming, routed message as seen by a handler.
+type Request struct {
+	ID   uint
+	Data []byte
+
+	// ContentType tells a CodecRegistry which Codec decodes Data.
+	// Zero-valued (ContentTypeJSON) for peers that don't set it.
+	ContentType message.ContentType
+}
+
+// Response is what a handler (or a Manager/Group broadcast) sends back
+// to one or more peers.
+type Response struct {
+	ID   uint
+	Data []byte
+
+	// ContentType tells the peer, and a CodecRegistry encoding this
+	// Response, which Codec Data is (or should be) encoded with.
+	ContentType message.ContentType
+}
@@ -0,0 +1,143 @@
+package router
+
+import "sync"
+
+// Executor dispatches handler invocations. Loop's default is a
+// fixed-size WorkerPool; implement this interface to plug in something
+// else (e.g. ants or tunny).
+type Executor interface {
+	// Submit schedules task to run, blocking until a worker is free or
+	// the Executor is closed.
+	Submit(task func())
+
+	// Close stops accepting new tasks and waits for in-flight ones to
+	// finish.
+	Close()
+}
+
+// WorkerPool is a fixed-size Executor: as many goroutines as the size
+// given to NewWorkerPool pull tasks off a
+// shared queue, bounding how many handlers can run at once so a flood
+// of requests can't spawn an unbounded number of goroutines.
+type WorkerPool struct {
+	tasks chan func()
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewWorkerPool creates a WorkerPool with size workers. size <= 0 is
+// treated as 1.
+func NewWorkerPool(size int) *WorkerPool {
+	if size <= 0 {
+		size = 1
+	}
+	p := &WorkerPool{
+		tasks: make(chan func()),
+		done:  make(chan struct{}),
+	}
+	p.wg.Add(size)
+	for i := 0; i < size; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+func (p *WorkerPool) worker() {
+	defer p.wg.Done()
+	for {
+		select {
+		case task, ok := <-p.tasks:
+			if !ok {
+				return
+			}
+			task()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Submit implements Executor.
+func (p *WorkerPool) Submit(task func()) {
+	select {
+	case p.tasks <- task:
+	case <-p.done:
+	}
+}
+
+// Close implements Executor.
+func (p *WorkerPool) Close() {
+	close(p.done)
+	p.wg.Wait()
+}
+
+// PerSessionExecutor wraps an Executor and guarantees tasks submitted
+// for the same session ID run one at a time and in submission order,
+// while tasks for different sessions still run concurrently on the
+// underlying Executor. This is the opt-in ordering mode for handlers
+// that can't tolerate out-of-order delivery to one peer.
+type PerSessionExecutor struct {
+	next Executor
+
+	mu      sync.Mutex
+	queues  map[string][]func()
+	running map[string]bool
+}
+
+// NewPerSessionExecutor wraps next.
+func NewPerSessionExecutor(next Executor) *PerSessionExecutor {
+	return &PerSessionExecutor{
+		next:    next,
+		queues:  make(map[string][]func()),
+		running: make(map[string]bool),
+	}
+}
+
+// SubmitFor schedules task to run after any task already queued for
+// sessionID.
+func (p *PerSessionExecutor) SubmitFor(sessionID string, task func()) {
+	p.mu.Lock()
+	if p.running[sessionID] {
+		p.queues[sessionID] = append(p.queues[sessionID], task)
+		p.mu.Unlock()
+		return
+	}
+	p.running[sessionID] = true
+	p.mu.Unlock()
+	p.runChain(sessionID, task)
+}
+
+func (p *PerSessionExecutor) runChain(sessionID string, task func()) {
+	p.next.Submit(func() {
+		task()
+		if next, ok := p.dequeue(sessionID); ok {
+			// Resubmitting from a new goroutine, rather than calling
+			// runChain inline, matters with a single-worker next: the
+			// worker running this task hasn't returned yet, so a
+			// synchronous Submit here would block waiting for a free
+			// worker that can only ever be this one, deadlocking the
+			// whole pool.
+			go p.runChain(sessionID, next)
+		}
+	})
+}
+
+func (p *PerSessionExecutor) dequeue(sessionID string) (func(), bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	q := p.queues[sessionID]
+	if len(q) == 0 {
+		delete(p.running, sessionID)
+		delete(p.queues, sessionID)
+		return nil, false
+	}
+	next := q[0]
+	p.queues[sessionID] = q[1:]
+	return next, true
+}
+
+// Close closes the underlying Executor. PerSessionExecutor has no
+// Submit method, so it is not itself an Executor.
+func (p *PerSessionExecutor) Close() {
+	p.next.Close()
+}
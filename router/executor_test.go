@@ -0,0 +1,173 @@
+package router
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPool_RunsTasksConcurrentlyUpToSize(t *testing.T) {
+	p := NewWorkerPool(2)
+	defer p.Close()
+
+	var running int32
+	var maxRunning int32
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		p.Submit(func() {
+			defer wg.Done()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+}
+
+func TestWorkerPool_ZeroOrNegativeSizeDefaultsToOne(t *testing.T) {
+	p := NewWorkerPool(0)
+	defer p.Close()
+
+	done := make(chan struct{})
+	p.Submit(func() { close(done) })
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("task never ran")
+	}
+}
+
+func TestWorkerPool_CloseWaitsForInFlightTasks(t *testing.T) {
+	p := NewWorkerPool(1)
+
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	p.Submit(func() {
+		close(started)
+		time.Sleep(20 * time.Millisecond)
+		close(finished)
+	})
+	<-started
+	p.Close()
+
+	select {
+	case <-finished:
+	default:
+		t.Fatal("Close returned before the in-flight task finished")
+	}
+}
+
+func TestPerSessionExecutor_OrdersTasksPerSession(t *testing.T) {
+	p := NewPerSessionExecutor(NewWorkerPool(4))
+	defer p.Close()
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		i := i
+		p.SubmitFor("sess-a", func() {
+			defer wg.Done()
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, order, 5)
+	for i, v := range order {
+		assert.Equal(t, i, v)
+	}
+}
+
+func TestPerSessionExecutor_SingleWorkerPoolDoesNotDeadlock(t *testing.T) {
+	p := NewPerSessionExecutor(NewWorkerPool(1))
+	defer p.Close()
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+
+	// Submit the second task for the same session from inside the
+	// first one, the way Router.Loop would if a second request arrived
+	// while the first was still being handled: this used to deadlock
+	// because runChain's resubmission called Submit synchronously from
+	// the pool's only worker goroutine.
+	wg.Add(1)
+	p.SubmitFor("sess-a", func() {
+		mu.Lock()
+		order = append(order, 0)
+		mu.Unlock()
+		p.SubmitFor("sess-a", func() {
+			defer wg.Done()
+			mu.Lock()
+			order = append(order, 1)
+			mu.Unlock()
+		})
+	})
+
+	select {
+	case <-waitGroupDone(&wg):
+	case <-time.After(time.Second):
+		t.Fatal("second task for the session never ran; PerSessionExecutor deadlocked on a single-worker pool")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []int{0, 1}, order)
+}
+
+func waitGroupDone(wg *sync.WaitGroup) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	return done
+}
+
+func TestPerSessionExecutor_DifferentSessionsRunConcurrently(t *testing.T) {
+	p := NewPerSessionExecutor(NewWorkerPool(2))
+	defer p.Close()
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+
+	p.SubmitFor("sess-a", func() {
+		started <- struct{}{}
+		<-release
+	})
+	p.SubmitFor("sess-b", func() {
+		started <- struct{}{}
+		<-release
+	})
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("first session's task never started")
+	}
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("second session's task never started concurrently with the first")
+	}
+	close(release)
+}
@@ -1,18 +1,19 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"github.com/DarthPestilane/easytcp/logger"
 	"github.com/DarthPestilane/easytcp/packet"
 	"github.com/DarthPestilane/easytcp/session"
-	"github.com/sirupsen/logrus"
+	"runtime"
 	"sync"
 )
 
 // Router is a router for incoming message.
 // Router routes the message to its handler and middlewares.
 type Router struct {
-	log *logrus.Entry
+	log logger.Logger
 
 	// handlerMapper maps message's ID to handler.
 	// Handler will be called around middlewares.
@@ -25,6 +26,26 @@ type Router struct {
 	// globalMiddlewares is a list of MiddlewareFunc.
 	// globalMiddlewares will be called before the ones in middlewaresMapper.
 	globalMiddlewares []MiddlewareFunc
+
+	// executor dispatches handler invocations instead of Loop spawning
+	// an unbounded goroutine per request.
+	executor Executor
+
+	// perSessionExec is set when Option.PerSessionOrdering is true, and
+	// wraps executor to serialize handlers per session ID.
+	perSessionExec *PerSessionExecutor
+}
+
+// Option configures a Router.
+type Option struct {
+	// Executor dispatches handler invocations. Defaults to a
+	// WorkerPool sized to runtime.NumCPU() when left nil.
+	Executor Executor
+
+	// PerSessionOrdering, when true, serializes handler execution for
+	// requests belonging to the same session, while different sessions
+	// still run concurrently on Executor.
+	PerSessionOrdering bool
 }
 
 // HandlerFunc is the function type for handlers.
@@ -46,34 +67,75 @@ var defaultHandler HandlerFunc = func(s session.Session, req *packet.Request) (*
 	return nil, nil
 }
 
-// New creates a new Router pointer.
-func New() *Router {
-	return &Router{
-		log:               logger.Default.WithField("scope", "router.Router"),
+// New creates a new Router pointer, logging through log. A nil log falls
+// back to logger.Default. opt customizes how Loop dispatches handlers;
+// the zero value is a WorkerPool sized to runtime.NumCPU().
+func New(log logger.Logger, opt ...Option) *Router {
+	if log == nil {
+		log = logger.Default
+	}
+	var o Option
+	if len(opt) != 0 {
+		o = opt[0]
+	}
+	if o.Executor == nil {
+		o.Executor = NewWorkerPool(runtime.NumCPU())
+	}
+	r := &Router{
+		log:               log.With(logger.Fields{"scope": "router.Router"}),
 		globalMiddlewares: make([]MiddlewareFunc, 0),
+		executor:          o.Executor,
+	}
+	if o.PerSessionOrdering {
+		r.perSessionExec = NewPerSessionExecutor(o.Executor)
 	}
+	return r
+}
+
+// Close stops the Router's Executor, waiting for in-flight handlers to
+// finish. Call it after all Loop goroutines have returned.
+func (r *Router) Close() {
+	r.executor.Close()
 }
 
 // Loop reads request from session.Session s in a loop way,
 // and routes the request to corresponding handler and middlewares if request is not nil.
-// Loop will break if session.Session s is closed.
-func (r *Router) Loop(s session.Session) {
+// Loop will break if session.Session s is closed, or if ctx is done, in which case Loop
+// waits for in-flight handlers spawned by this call to return before exiting, so callers
+// can embed easytcp in a supervisor and get clean rolling restarts.
+func (r *Router) Loop(ctx context.Context, s session.Session) {
+	var wg sync.WaitGroup
+	defer func() {
+		wg.Wait()
+		r.log.With(logger.Fields{"sid": s.ID()}).Tracef("loop exit")
+	}()
 	for {
-		req, ok := <-s.RecvReq()
-		if !ok {
-			r.log.WithField("sid", s.ID()).Tracef("loop stopped since session is closed")
-			break
-		}
-		if req == nil {
-			continue
-		}
-		go func() {
-			if err := r.handleReq(s, req); err != nil {
-				r.log.WithField("sid", s.ID()).Tracef("handle request err: %s", err)
+		select {
+		case <-ctx.Done():
+			r.log.With(logger.Fields{"sid": s.ID()}).Tracef("loop stopped since context is done")
+			return
+		case req, ok := <-s.RecvReq():
+			if !ok {
+				r.log.With(logger.Fields{"sid": s.ID()}).Tracef("loop stopped since session is closed")
+				return
 			}
-		}()
+			if req == nil {
+				continue
+			}
+			wg.Add(1)
+			task := func() {
+				defer wg.Done()
+				if err := r.handleReq(s, req); err != nil {
+					r.log.With(logger.Fields{"sid": s.ID()}).Tracef("handle request err: %s", err)
+				}
+			}
+			if r.perSessionExec != nil {
+				r.perSessionExec.SubmitFor(s.ID(), task)
+			} else {
+				r.executor.Submit(task)
+			}
+		}
 	}
-	r.log.WithField("sid", s.ID()).Tracef("loop exit")
 }
 
 // handleReq routes the packet.Request req to corresponding handler and middlewares,
@@ -1,48 +1,222 @@
 package easytcp
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"sync"
+
+	"github.com/DarthPestilane/easytcp/heartbeat"
+	"github.com/DarthPestilane/easytcp/logger"
+	"github.com/DarthPestilane/easytcp/metrics"
+	"github.com/DarthPestilane/easytcp/packet"
+	"github.com/DarthPestilane/easytcp/router"
 )
 
+// ServerOption customizes how a Server behaves. The zero value is a
+// Server listening on plain TCP, matching the previous behaviour.
+type ServerOption struct {
+	// Transport controls how the server accepts incoming peers.
+	// Defaults to &TCPTransport{} when left nil, so callers can opt
+	// into TLS or UDP/DTLS-style transports without forking the
+	// accept loop.
+	Transport Transport
+
+	// Logger is used for the server's own diagnostic logging. Defaults
+	// to logger.Default (logrus) when left nil. Ship adapters exist in
+	// the logger package for zap, zerolog and log/slog.
+	Logger logger.Logger
+
+	// Packer frames requests/responses on the wire. Defaults to
+	// &packet.DefaultPacker{} when left nil.
+	Packer packet.Packer
+
+	// WorkerPoolSize bounds how many handlers can run at once, via a
+	// router.WorkerPool. Defaults to runtime.NumCPU() when <= 0.
+	WorkerPoolSize int
+
+	// PerSessionOrdering, when true, serializes handler execution for
+	// requests belonging to the same session, while different sessions
+	// still run concurrently across the worker pool.
+	PerSessionOrdering bool
+
+	// Heartbeat, when set, pings every connected session on Interval
+	// and closes it once it's gone Timeout without a received frame.
+	// Left nil, no heartbeat/idle-eviction runs at all.
+	Heartbeat *heartbeat.Config
+
+	// CodecRegistry is what Server.Codecs returns, for handlers that
+	// want to decode/encode a typed payload out of a request's raw
+	// bytes instead of handling packet.Request/Response.Data directly.
+	// Defaults to NewCodecRegistry() when left nil.
+	CodecRegistry *CodecRegistry
+}
+
 type Server struct {
 	// Addr the address: 127.0.0.1
 	Addr string
 	// Port eg: 8765
 	Port int
 
-	mu sync.Mutex
+	opt       ServerOption
+	log       logger.Logger
+	packer    packet.Packer
+	router    *router.Router
+	heartbeat *heartbeat.Tracker
+	codecs    *CodecRegistry
 
 	listener net.Listener
 
-	// route handlers
-	// key is the route path
-	handler map[string]HandlerFunc
-
 	// hook functions
 	onConnectedFn  ConnectHookFunc
 	onDisconnectFn ConnectHookFunc
+
+	// lifecycle
+	ctx      context.Context
+	cancel   context.CancelFunc
+	ready    chan struct{}
+	done     chan struct{}
+	serveErr error
+
+	conns sync.Map // net.Conn -> *Connection, tracked for graceful Shutdown
 }
 
-type HandlerFunc func(ctx *Context)
 type ConnectHookFunc func(conn *Connection)
 
-func NewServer(addr string, port int) *Server {
-	return &Server{
-		Addr:    addr,
-		Port:    port,
-		handler: make(map[string]HandlerFunc),
+func NewServer(addr string, port int, opt ...ServerOption) *Server {
+	s := &Server{
+		Addr:  addr,
+		Port:  port,
+		ready: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if len(opt) != 0 {
+		s.opt = opt[0]
 	}
+	if s.opt.Transport == nil {
+		s.opt.Transport = &TCPTransport{}
+	}
+	s.log = s.opt.Logger
+	if s.log == nil {
+		s.log = logger.Default
+	}
+	s.packer = s.opt.Packer
+	if s.packer == nil {
+		s.packer = &packet.DefaultPacker{}
+	}
+	s.codecs = s.opt.CodecRegistry
+	if s.codecs == nil {
+		s.codecs = NewCodecRegistry()
+	}
+	var executor router.Executor
+	if s.opt.WorkerPoolSize > 0 {
+		executor = router.NewWorkerPool(s.opt.WorkerPoolSize)
+	}
+	s.router = router.New(s.log, router.Option{
+		Executor:           executor,
+		PerSessionOrdering: s.opt.PerSessionOrdering,
+	})
+	s.router.RegisterMiddleware(metrics.Middleware())
+	if s.opt.Heartbeat != nil {
+		s.heartbeat = heartbeat.NewTracker(*s.opt.Heartbeat)
+		s.router.RegisterMiddleware(s.heartbeat.Middleware())
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	return s
 }
 
-func (s *Server) Serve() error {
-	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", s.Addr, s.Port))
+// Ready returns a channel that's closed once the server is listening
+// and accepting connections.
+func (s *Server) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Start begins listening and accepting connections in the background.
+// It returns as soon as the listener is up, or immediately with an
+// error if listening failed. Use Wait to block for the server's
+// terminal error, or Ready to know when it's safe to dial.
+func (s *Server) Start() error {
+	lis, err := s.opt.Transport.Listen(fmt.Sprintf("%s:%d", s.Addr, s.Port))
 	if err != nil {
-		return fmt.Errorf("listen tcp failed: %w", err)
+		return fmt.Errorf("listen failed: %w", err)
 	}
 	s.listener = lis
-	return s.keepAccepting()
+	close(s.ready)
+
+	go func() {
+		s.serveErr = s.keepAccepting()
+		close(s.done)
+	}()
+	return nil
+}
+
+// Wait blocks until the server stops accepting connections, either
+// because the listener errored or Stop/Shutdown was called, and
+// returns the terminal error, if any.
+func (s *Server) Wait() error {
+	<-s.done
+	return s.serveErr
+}
+
+// Serve starts the server and blocks until it stops, returning the
+// terminal error. It's equivalent to calling Start followed by Wait.
+func (s *Server) Serve() error {
+	if err := s.Start(); err != nil {
+		return err
+	}
+	return s.Wait()
+}
+
+// Stop stops accepting connections and closes all active ones
+// immediately, without waiting for in-flight handlers to finish.
+func (s *Server) Stop() error {
+	return s.Shutdown(s.ctx)
+}
+
+// Shutdown stops accepting new connections, closes the listener, then
+// signals every active connection's context to stop reading new
+// requests, which unblocks their in-flight router.Router.Loop calls
+// (after draining any handlers already dispatched). It waits for that
+// to happen up to ctx's deadline, then force-closes any stragglers.
+// Handlers still running on the worker pool are waited for within the
+// same deadline.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.cancel() // unblocks every Connection.KeepReading and router.Loop via their shared s.ctx
+	if s.listener != nil {
+		_ = s.listener.Close()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		s.conns.Range(func(_, v interface{}) bool {
+			conn := v.(*Connection)
+			<-conn.Closed
+			return true
+		})
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		s.conns.Range(func(_, v interface{}) bool {
+			_ = v.(*Connection).Close()
+			return true
+		})
+	}
+
+	// Closing the router waits for handlers already running on its
+	// workers; that wait is bounded by ctx as well.
+	routerClosed := make(chan struct{})
+	go func() {
+		s.router.Close()
+		close(routerClosed)
+	}()
+	select {
+	case <-routerClosed:
+	case <-ctx.Done():
+	}
+	return nil
 }
 
 func (s *Server) OnConnected(fn ConnectHookFunc) {
@@ -53,31 +227,78 @@ func (s *Server) OnDisconnect(fn ConnectHookFunc) {
 	s.onDisconnectFn = fn
 }
 
-func (s *Server) AddRoute(routePath string, fn HandlerFunc) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.handler[routePath] = fn
+// Register stores h, and optional per-route middlewares m, as the
+// handler for requests with the given route id.
+func (s *Server) Register(id uint, h router.HandlerFunc, m ...router.MiddlewareFunc) {
+	s.router.Register(id, h, m...)
+}
+
+// RegisterMiddleware stores m as global middlewares, run for every
+// route ahead of any per-route ones.
+func (s *Server) RegisterMiddleware(m ...router.MiddlewareFunc) {
+	s.router.RegisterMiddleware(m...)
+}
+
+// Codecs returns the Server's CodecRegistry, for handlers that want to
+// decode a request or encode a response via a Codec instead of handling
+// packet.Request/Response.Data directly.
+func (s *Server) Codecs() *CodecRegistry {
+	return s.codecs
 }
 
 func (s *Server) keepAccepting() error {
 	for {
 		rawConn, err := s.listener.Accept()
 		if err != nil {
-			return err
+			select {
+			case <-s.ctx.Done():
+				// Shutdown/Stop closed the listener; this isn't a
+				// terminal error worth reporting to Wait's caller.
+				return nil
+			default:
+				s.log.Errorf("accept failed: %s", err)
+				return err
+			}
 		}
 		conn := NewConnection(rawConn, s)
+		s.conns.Store(rawConn, conn)
 
-		go conn.KeepReading()
-		go conn.KeepWriting()
+		go s.serveConn(conn)
+	}
+}
 
-		if s.onConnectedFn != nil {
-			s.onConnectedFn(conn)
-		}
+// serveConn reads and routes requests for one connection until it's
+// closed or the server is shutting down, then runs the connect/
+// disconnect hooks around that.
+func (s *Server) serveConn(conn *Connection) {
+	metrics.ActiveSessions.Inc()
+	defer metrics.ActiveSessions.Dec()
+	defer s.conns.Delete(conn.conn)
 
-		<-conn.Closed
+	go conn.KeepReading(s.ctx)
 
-		if s.onDisconnectFn != nil {
-			s.onDisconnectFn(conn)
-		}
+	if s.heartbeat != nil {
+		go func() {
+			// Run returns nil when conn went idle past Timeout, and
+			// ctx.Err() when s.ctx ended it instead; only the idle case
+			// needs us to close conn ourselves, since shutdown already
+			// does that via Connection.KeepReading.
+			if err := s.heartbeat.Run(s.ctx, conn); err == nil {
+				_ = conn.Close()
+			}
+		}()
+	}
+
+	if s.onConnectedFn != nil {
+		s.onConnectedFn(conn)
+	}
+
+	// Loop blocks until conn is closed or s.ctx is done; in the latter
+	// case it still waits for handlers it already dispatched to this
+	// connection to finish before returning, so Shutdown's drain is real.
+	s.router.Loop(s.ctx, conn)
+
+	if s.onDisconnectFn != nil {
+		s.onDisconnectFn(conn)
 	}
 }
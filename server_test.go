@@ -0,0 +1,185 @@
+package easytcp
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DarthPestilane/easytcp/packet"
+	"github.com/DarthPestilane/easytcp/router"
+	"github.com/DarthPestilane/easytcp/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dialServer(t *testing.T, s *Server) net.Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", s.listener.Addr().String())
+	require.NoError(t, err)
+	return conn
+}
+
+// TestServer_RoutesARealRequestEndToEnd dials a real Server over TCP,
+// sends a framed request and asserts the registered handler's response
+// comes back on the wire - proving Connection/router.Router are
+// actually connected, not just independently testable in isolation.
+func TestServer_RoutesARealRequestEndToEnd(t *testing.T) {
+	s := NewServer("127.0.0.1", 0)
+	s.Register(1, func(sess session.Session, req *packet.Request) (*packet.Response, error) {
+		return &packet.Response{ID: 2, Data: []byte("pong: " + string(req.Data))}, nil
+	})
+
+	require.NoError(t, s.Start())
+	defer s.Stop() // nolint
+	<-s.Ready()
+
+	client := dialServer(t, s)
+	defer client.Close() // nolint
+
+	packer := &packet.DefaultPacker{}
+	frame, err := packer.Pack(&packet.Response{ID: 1, Data: []byte("ping")})
+	require.NoError(t, err)
+	_, err = client.Write(frame)
+	require.NoError(t, err)
+
+	client.SetReadDeadline(time.Now().Add(2 * time.Second)) // nolint
+	resp, err := packer.Unpack(client)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), resp.ID)
+	assert.Equal(t, "pong: ping", string(resp.Data))
+}
+
+// TestServer_HandlerRoundTripsThroughCodecRegistry proves Server.Codecs
+// is reachable from a handler and actually decodes/encodes against the
+// content-type carried on the wire by packet.Request/Response.
+func TestServer_HandlerRoundTripsThroughCodecRegistry(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	s := NewServer("127.0.0.1", 0)
+	s.Register(1, func(sess session.Session, req *packet.Request) (*packet.Response, error) {
+		var in payload
+		if err := s.Codecs().DecodeRequest(req, &in); err != nil {
+			return nil, err
+		}
+		return s.Codecs().EncodeResponse(2, req.ContentType, payload{Name: "hello, " + in.Name})
+	})
+
+	require.NoError(t, s.Start())
+	defer s.Stop() // nolint
+	<-s.Ready()
+
+	client := dialServer(t, s)
+	defer client.Close() // nolint
+
+	packer := &packet.DefaultPacker{}
+	reqResp, err := s.Codecs().EncodeResponse(1, ContentTypeJSON, payload{Name: "world"})
+	require.NoError(t, err)
+	frame, err := packer.Pack(reqResp)
+	require.NoError(t, err)
+	_, err = client.Write(frame)
+	require.NoError(t, err)
+
+	client.SetReadDeadline(time.Now().Add(2 * time.Second)) // nolint
+	resp, err := packer.Unpack(client)
+	require.NoError(t, err)
+
+	var out payload
+	require.NoError(t, NewCodecRegistry().DecodeRequest(&packet.Request{Data: resp.Data, ContentType: resp.ContentType}, &out))
+	assert.Equal(t, "hello, world", out.Name)
+}
+
+// TestServer_ShutdownDrainsInFlightHandler proves Shutdown's drain is
+// real: it waits for a handler already dispatched to a connection to
+// finish - rather than returning as soon as ctx is canceled - as long
+// as the handler finishes before the ctx passed to Shutdown expires.
+func TestServer_ShutdownDrainsInFlightHandler(t *testing.T) {
+	handlerStarted := make(chan struct{})
+	handlerDone := make(chan struct{})
+
+	s := NewServer("127.0.0.1", 0)
+	s.Register(1, func(sess session.Session, req *packet.Request) (*packet.Response, error) {
+		close(handlerStarted)
+		time.Sleep(50 * time.Millisecond)
+		close(handlerDone)
+		return nil, nil
+	})
+
+	require.NoError(t, s.Start())
+	<-s.Ready()
+
+	client := dialServer(t, s)
+	defer client.Close() // nolint
+
+	packer := &packet.DefaultPacker{}
+	frame, err := packer.Pack(&packet.Response{ID: 1})
+	require.NoError(t, err)
+	_, err = client.Write(frame)
+	require.NoError(t, err)
+
+	<-handlerStarted
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.Shutdown(ctx))
+
+	select {
+	case <-handlerDone:
+	default:
+		t.Fatal("Shutdown returned before the in-flight handler finished")
+	}
+}
+
+func TestServer_RegisteredMiddlewareRunsAroundHandler(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	done := make(chan struct{})
+
+	s := NewServer("127.0.0.1", 0)
+	var mw router.MiddlewareFunc = func(next router.HandlerFunc) router.HandlerFunc {
+		return func(sess session.Session, req *packet.Request) (*packet.Response, error) {
+			mu.Lock()
+			order = append(order, "before")
+			mu.Unlock()
+			resp, err := next(sess, req)
+			mu.Lock()
+			order = append(order, "after")
+			mu.Unlock()
+			close(done)
+			return resp, err
+		}
+	}
+	s.RegisterMiddleware(mw)
+	s.Register(1, func(sess session.Session, req *packet.Request) (*packet.Response, error) {
+		mu.Lock()
+		order = append(order, "handler")
+		mu.Unlock()
+		return nil, nil
+	})
+
+	require.NoError(t, s.Start())
+	defer s.Stop() // nolint
+	<-s.Ready()
+
+	client := dialServer(t, s)
+	defer client.Close() // nolint
+
+	packer := &packet.DefaultPacker{}
+	frame, err := packer.Pack(&packet.Response{ID: 1})
+	require.NoError(t, err)
+	_, err = client.Write(frame)
+	require.NoError(t, err)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("middleware/handler chain never completed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"before", "handler", "after"}, order)
+}
@@ -0,0 +1,59 @@
+package session
+
+import (
+	"sync"
+
+	"github.com/DarthPestilane/easytcp/packet"
+)
+
+// Group tracks membership of sessions in named groups ("rooms"), so
+// handlers can reply to more than just the session that sent the
+// request they're handling - the basis for chat/signaling style
+// fan-out.
+type Group struct {
+	rooms sync.Map // groupID -> *sync.Map (sessionID -> Session)
+}
+
+// NewGroup creates an empty Group.
+func NewGroup() *Group {
+	return &Group{}
+}
+
+// Join adds sess to groupID, creating the group if it doesn't exist
+// yet. Join is a no-op if sess is nil.
+func (g *Group) Join(groupID string, sess Session) {
+	if sess == nil {
+		return
+	}
+	room, _ := g.rooms.LoadOrStore(groupID, &sync.Map{})
+	room.(*sync.Map).Store(sess.ID(), sess)
+}
+
+// Leave removes sess from groupID, if it's a member. Leave is a no-op
+// if sess is nil or groupID doesn't exist.
+func (g *Group) Leave(groupID string, sess Session) {
+	if sess == nil {
+		return
+	}
+	if room, ok := g.rooms.Load(groupID); ok {
+		room.(*sync.Map).Delete(sess.ID())
+	}
+}
+
+// BroadcastTo sends resp to every session in groupID. It's a no-op if
+// groupID doesn't exist, and keeps going on error so one bad peer can't
+// stop delivery to the rest of the group.
+func (g *Group) BroadcastTo(groupID string, resp *packet.Response) error {
+	room, ok := g.rooms.Load(groupID)
+	if !ok {
+		return nil
+	}
+	var firstErr error
+	room.(*sync.Map).Range(func(_, v interface{}) bool {
+		if _, err := v.(Session).SendResp(resp); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return true
+	})
+	return firstErr
+}
@@ -0,0 +1,130 @@
+package session
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/DarthPestilane/easytcp/packet"
+	"github.com/DarthPestilane/easytcp/session/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_JoinLeave(t *testing.T) {
+	g := NewGroup()
+	assert.NotPanics(t, func() { g.Join("room", nil) })
+	assert.NotPanics(t, func() { g.Leave("room", nil) })
+	assert.NotPanics(t, func() { g.Leave("no such room", nil) })
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mock.NewMockSession(ctrl)
+	sess.EXPECT().ID().AnyTimes().Return("sess id")
+
+	g.Join("room", sess)
+	room, ok := g.rooms.Load("room")
+	assert.True(t, ok)
+	_, ok = room.(*sync.Map).Load(sess.ID())
+	assert.True(t, ok)
+
+	g.Leave("room", sess)
+	_, ok = room.(*sync.Map).Load(sess.ID())
+	assert.False(t, ok)
+}
+
+func TestGroup_JoinLeaveConcurrently(t *testing.T) {
+	g := NewGroup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		sess := mock.NewMockSession(ctrl)
+		sess.EXPECT().ID().AnyTimes().Return(string(rune(i)))
+
+		wg.Add(1)
+		go func(sess Session) {
+			defer wg.Done()
+			g.Join("room", sess)
+			g.Leave("room", sess)
+		}(sess)
+	}
+	wg.Wait()
+
+	room, ok := g.rooms.Load("room")
+	assert.True(t, ok)
+	left := 0
+	room.(*sync.Map).Range(func(_, _ interface{}) bool {
+		left++
+		return true
+	})
+	assert.Equal(t, 0, left)
+}
+
+func TestGroup_BroadcastTo_NoSuchGroup(t *testing.T) {
+	g := NewGroup()
+	assert.NoError(t, g.BroadcastTo("no such room", &packet.Response{}))
+}
+
+func TestGroup_BroadcastTo_KeepsGoingAfterAFailingPeer(t *testing.T) {
+	g := NewGroup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resp := &packet.Response{ID: 1}
+
+	failErr := errors.New("send failed")
+	bad := mock.NewMockSession(ctrl)
+	bad.EXPECT().ID().AnyTimes().Return("bad")
+	bad.EXPECT().SendResp(resp).Return(0, failErr)
+
+	var mu sync.Mutex
+	var got []string
+	good := mock.NewMockSession(ctrl)
+	good.EXPECT().ID().AnyTimes().Return("good")
+	good.EXPECT().SendResp(resp).DoAndReturn(func(r *packet.Response) (int, error) {
+		mu.Lock()
+		got = append(got, "good")
+		mu.Unlock()
+		return 0, nil
+	})
+
+	g.Join("room", bad)
+	g.Join("room", good)
+
+	err := g.BroadcastTo("room", resp)
+	assert.ErrorIs(t, err, failErr)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"good"}, got)
+}
+
+func TestGroup_BroadcastTo_FirstErrWins(t *testing.T) {
+	g := NewGroup()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resp := &packet.Response{ID: 1}
+	firstErr := errors.New("first")
+	secondErr := errors.New("second")
+
+	sess1 := mock.NewMockSession(ctrl)
+	sess1.EXPECT().ID().AnyTimes().Return("sess1")
+	sess1.EXPECT().SendResp(resp).Return(0, firstErr)
+
+	sess2 := mock.NewMockSession(ctrl)
+	sess2.EXPECT().ID().AnyTimes().Return("sess2")
+	sess2.EXPECT().SendResp(resp).Return(0, secondErr)
+
+	g.Join("room", sess1)
+	g.Join("room", sess2)
+
+	err := g.BroadcastTo("room", resp)
+	assert.True(t, errors.Is(err, firstErr) || errors.Is(err, secondErr))
+}
@@ -0,0 +1,69 @@
+package session
+
+import (
+	"sync"
+
+	"github.com/DarthPestilane/easytcp/packet"
+)
+
+var (
+	manager     *Manager
+	managerOnce sync.Once
+)
+
+// Sessions returns the package-level default Manager, creating it on
+// first use. It's safe to call concurrently.
+func Sessions() *Manager {
+	managerOnce.Do(func() { manager = &Manager{} })
+	return manager
+}
+
+// Manager tracks connected sessions, keyed by Session.ID(). The zero
+// value is ready to use.
+type Manager struct {
+	Sessions sync.Map // id -> Session
+}
+
+// Add stores sess, keyed by its ID. Add is a no-op if sess is nil.
+func (m *Manager) Add(sess Session) {
+	if sess == nil {
+		return
+	}
+	m.Sessions.Store(sess.ID(), sess)
+}
+
+// Get returns the session stored under id, or nil if there isn't one.
+func (m *Manager) Get(id string) Session {
+	v, ok := m.Sessions.Load(id)
+	if !ok {
+		return nil
+	}
+	return v.(Session)
+}
+
+// Remove deletes the session stored under id, if any.
+func (m *Manager) Remove(id string) {
+	m.Sessions.Delete(id)
+}
+
+// Range calls fn for every tracked session until fn returns false. The
+// iteration order is not defined, matching sync.Map.Range.
+func (m *Manager) Range(fn func(Session) bool) {
+	m.Sessions.Range(func(_, v interface{}) bool {
+		return fn(v.(Session))
+	})
+}
+
+// Broadcast sends resp to every tracked session. It keeps going on
+// error so one bad peer can't stop delivery to the rest, and returns
+// the first error encountered, if any.
+func (m *Manager) Broadcast(resp *packet.Response) error {
+	var firstErr error
+	m.Range(func(s Session) bool {
+		if _, err := s.SendResp(resp); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return true
+	})
+	return firstErr
+}
@@ -56,9 +56,47 @@ func TestManager_Get(t *testing.T) {
 }
 
 func TestManager_Range(t *testing.T) {
+	mg := &Manager{}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
+	sess1 := mock.NewMockSession(ctrl)
+	sess1.EXPECT().ID().MinTimes(1).Return("sess1")
+	sess2 := mock.NewMockSession(ctrl)
+	sess2.EXPECT().ID().MinTimes(1).Return("sess2")
+
+	mg.Add(sess1)
+	mg.Add(sess2)
+
+	seen := make(map[string]bool)
+	mg.Range(func(s Session) bool {
+		seen[s.ID()] = true
+		return true
+	})
+	assert.Equal(t, map[string]bool{"sess1": true, "sess2": true}, seen)
+
+	seen = make(map[string]bool)
+	mg.Range(func(s Session) bool {
+		seen[s.ID()] = true
+		return false
+	})
+	assert.Len(t, seen, 1)
 }
 
 func TestManager_Remove(t *testing.T) {
+	mg := &Manager{}
+	assert.NotPanics(t, func() { mg.Remove("not found") })
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mock.NewMockSession(ctrl)
+	sess.EXPECT().ID().MinTimes(1).Return("sess id")
+
+	mg.Add(sess)
+	assert.NotNil(t, mg.Get(sess.ID()))
 
+	mg.Remove(sess.ID())
+	assert.Nil(t, mg.Get(sess.ID()))
 }
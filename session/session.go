@@ -0,0 +1,21 @@
+// Package session models a connected peer as seen by router.Router: a
+// stream of incoming packet.Request values to route, and a way to send
+// packet.Response values back.
+package session
+
+import "github.com/DarthPestilane/easytcp/packet"
+
+// Session represents one connected peer. easytcp.Connection implements
+// it directly over its net.Conn.
+type Session interface {
+	// ID returns the session's unique identifier.
+	ID() string
+
+	// RecvReq returns the channel router.Router reads incoming requests
+	// from. The channel is closed once the session is closed.
+	RecvReq() <-chan *packet.Request
+
+	// SendResp sends resp to the peer, returning the number of bytes
+	// written.
+	SendResp(resp *packet.Response) (int, error)
+}
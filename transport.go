@@ -0,0 +1,242 @@
+package easytcp
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Transport abstracts how a Server accepts incoming peers, so the
+// server loop doesn't need to know whether it's listening on a raw
+// TCP socket, a TLS-wrapped one, or a datagram transport like UDP.
+//
+// Implementations must return a net.Listener that yields one net.Conn
+// per logical peer, even when the underlying medium is connectionless.
+type Transport interface {
+	// Listen starts listening on addr and returns a net.Listener.
+	Listen(addr string) (net.Listener, error)
+}
+
+// TCPTransport is the default Transport, listening on a plain TCP socket.
+type TCPTransport struct{}
+
+// Listen implements the Transport interface.
+func (t *TCPTransport) Listen(addr string) (net.Listener, error) {
+	return net.Listen("tcp", addr)
+}
+
+// TLSTransport listens on a TCP socket and wraps accepted connections
+// with TLS using Config.
+type TLSTransport struct {
+	Config *tls.Config
+}
+
+// Listen implements the Transport interface.
+func (t *TLSTransport) Listen(addr string) (net.Listener, error) {
+	if t.Config == nil {
+		return nil, fmt.Errorf("tls transport: Config must not be nil")
+	}
+	return tls.Listen("tcp", addr, t.Config)
+}
+
+// UDPTransport listens on a UDP socket and frames each incoming
+// datagram as a single read on its peer's net.Conn (one packet in, one
+// packet out). It does not bypass the Server's Packer: Connection still
+// unpacks each peer's reads with it, and the length-prefix DefaultPacker
+// reads its 9-byte header with a Read of its own, which any longer
+// datagram fails with io.ErrShortBuffer. Listen opens its own socket
+// with net.ListenUDP and sends and receives plain, unauthenticated UDP;
+// there is no way to wrap that socket in a DTLS layer.
+type UDPTransport struct {
+	// IdleTimeout evicts a peer that hasn't sent a datagram for this
+	// long. Zero disables eviction.
+	IdleTimeout time.Duration
+}
+
+// Listen implements the Transport interface.
+func (t *UDPTransport) Listen(addr string) (net.Listener, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("resolve udp addr failed: %w", err)
+	}
+	pc, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return nil, fmt.Errorf("listen udp failed: %w", err)
+	}
+	l := &packetListener{
+		pc:          pc,
+		idleTimeout: t.IdleTimeout,
+		accept:      make(chan net.Conn),
+		closed:      make(chan struct{}),
+		peers:       make(map[string]*packetConn),
+	}
+	go l.demux()
+	return l, nil
+}
+
+// packetListener adapts a net.PacketConn into a net.Listener by
+// demuxing datagrams per remote address into a *packetConn each.
+type packetListener struct {
+	pc          net.PacketConn
+	idleTimeout time.Duration
+
+	mu    sync.Mutex
+	peers map[string]*packetConn
+
+	accept chan net.Conn
+	closed chan struct{}
+}
+
+func (l *packetListener) demux() {
+	buf := make([]byte, 64*1024)
+	for {
+		n, remote, err := l.pc.ReadFrom(buf)
+		if err != nil {
+			close(l.accept)
+			return
+		}
+		frame := make([]byte, n)
+		copy(frame, buf[:n])
+
+		l.mu.Lock()
+		pConn, ok := l.peers[remote.String()]
+		if !ok {
+			pConn = newPacketConn(l.pc, remote, l.idleTimeout, func() {
+				l.mu.Lock()
+				delete(l.peers, remote.String())
+				l.mu.Unlock()
+			})
+			l.peers[remote.String()] = pConn
+		}
+		l.mu.Unlock()
+
+		if !ok {
+			select {
+			case l.accept <- pConn:
+			case <-l.closed:
+				return
+			}
+		}
+		pConn.deliver(frame)
+	}
+}
+
+// Accept implements net.Listener.
+func (l *packetListener) Accept() (net.Conn, error) {
+	c, ok := <-l.accept
+	if !ok {
+		return nil, fmt.Errorf("udp transport: listener closed")
+	}
+	return c, nil
+}
+
+// Close implements net.Listener.
+func (l *packetListener) Close() error {
+	close(l.closed)
+	return l.pc.Close()
+}
+
+// Addr implements net.Listener.
+func (l *packetListener) Addr() net.Addr {
+	return l.pc.LocalAddr()
+}
+
+// packetConn presents one remote peer's datagram stream as a net.Conn,
+// framing one datagram per Read, one datagram per Write.
+type packetConn struct {
+	pc     net.PacketConn
+	remote net.Addr
+
+	in         chan []byte
+	lastActive int64 // unix nanos, updated atomically
+	closeOnce  sync.Once
+	closed     chan struct{}
+	onClose    func()
+}
+
+func newPacketConn(pc net.PacketConn, remote net.Addr, idleTimeout time.Duration, onClose func()) *packetConn {
+	c := &packetConn{
+		pc:      pc,
+		remote:  remote,
+		in:      make(chan []byte, 64),
+		closed:  make(chan struct{}),
+		onClose: onClose,
+	}
+	atomic.StoreInt64(&c.lastActive, time.Now().UnixNano())
+	if idleTimeout > 0 {
+		go c.watchIdle(idleTimeout)
+	}
+	return c
+}
+
+func (c *packetConn) watchIdle(timeout time.Duration) {
+	ticker := time.NewTicker(timeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+			last := time.Unix(0, atomic.LoadInt64(&c.lastActive))
+			if time.Since(last) > timeout {
+				_ = c.Close()
+				return
+			}
+		}
+	}
+}
+
+func (c *packetConn) deliver(frame []byte) {
+	atomic.StoreInt64(&c.lastActive, time.Now().UnixNano())
+	select {
+	case c.in <- frame:
+	case <-c.closed:
+	}
+}
+
+// Read implements net.Conn. Since this transport frames one datagram
+// per Read, a datagram that doesn't fit in b can't be partially
+// delivered without corrupting that guarantee: rather than silently
+// truncating it, Read drops the oversized datagram and returns
+// io.ErrShortBuffer, mirroring net.PacketConn.ReadFrom's own contract
+// for the same situation.
+func (c *packetConn) Read(b []byte) (int, error) {
+	select {
+	case frame, ok := <-c.in:
+		if !ok {
+			return 0, fmt.Errorf("udp transport: conn closed")
+		}
+		if len(frame) > len(b) {
+			return 0, io.ErrShortBuffer
+		}
+		n := copy(b, frame)
+		return n, nil
+	case <-c.closed:
+		return 0, fmt.Errorf("udp transport: conn closed")
+	}
+}
+
+func (c *packetConn) Write(b []byte) (int, error) {
+	return c.pc.WriteTo(b, c.remote)
+}
+
+func (c *packetConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		if c.onClose != nil {
+			c.onClose()
+		}
+	})
+	return nil
+}
+
+func (c *packetConn) LocalAddr() net.Addr  { return c.pc.LocalAddr() }
+func (c *packetConn) RemoteAddr() net.Addr { return c.remote }
+
+func (c *packetConn) SetDeadline(t time.Time) error      { return nil }
+func (c *packetConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *packetConn) SetWriteDeadline(t time.Time) error { return nil }
@@ -0,0 +1,160 @@
+package easytcp
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTCPTransport_Listen(t *testing.T) {
+	tr := &TCPTransport{}
+	lis, err := tr.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close() // nolint
+
+	go func() {
+		conn, err := lis.Accept()
+		require.NoError(t, err)
+		_, _ = conn.Write([]byte("pong"))
+	}()
+
+	client, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err)
+	defer client.Close() // nolint
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(client, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", string(buf))
+}
+
+func TestTLSTransport_Listen(t *testing.T) {
+	cert := selfSignedCert(t)
+
+	tr := &TLSTransport{Config: &tls.Config{Certificates: []tls.Certificate{cert}}}
+	lis, err := tr.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close() // nolint
+
+	go func() {
+		conn, err := lis.Accept()
+		require.NoError(t, err)
+		_, _ = conn.Write([]byte("pong"))
+	}()
+
+	client, err := tls.Dial("tcp", lis.Addr().String(), &tls.Config{InsecureSkipVerify: true}) // nolint:gosec
+	require.NoError(t, err)
+	defer client.Close() // nolint
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(client, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", string(buf))
+}
+
+func TestUDPTransport_Listen(t *testing.T) {
+	tr := &UDPTransport{}
+	lis, err := tr.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close() // nolint
+
+	client, err := net.Dial("udp", lis.Addr().String())
+	require.NoError(t, err)
+	defer client.Close() // nolint
+
+	_, err = client.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	conn, err := lis.Accept()
+	require.NoError(t, err)
+
+	buf := make([]byte, 16)
+	n, err := conn.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(buf[:n]))
+}
+
+func TestUDPTransport_OversizedDatagramIsNotSilentlyTruncated(t *testing.T) {
+	tr := &UDPTransport{}
+	lis, err := tr.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close() // nolint
+
+	client, err := net.Dial("udp", lis.Addr().String())
+	require.NoError(t, err)
+	defer client.Close() // nolint
+
+	_, err = client.Write([]byte("this payload is longer than the reader's buffer"))
+	require.NoError(t, err)
+
+	conn, err := lis.Accept()
+	require.NoError(t, err)
+
+	small := make([]byte, 4)
+	n, err := conn.Read(small)
+	assert.Equal(t, 0, n)
+	assert.ErrorIs(t, err, io.ErrShortBuffer)
+}
+
+func TestUDPTransport_IdleTimeoutEvicts(t *testing.T) {
+	tr := &UDPTransport{IdleTimeout: 20 * time.Millisecond}
+	lis, err := tr.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close() // nolint
+
+	client, err := net.Dial("udp", lis.Addr().String())
+	require.NoError(t, err)
+	defer client.Close() // nolint
+
+	_, err = client.Write([]byte("hi"))
+	require.NoError(t, err)
+
+	conn, err := lis.Accept()
+	require.NoError(t, err)
+
+	buf := make([]byte, 16)
+	_, err = conn.Read(buf)
+	require.NoError(t, err)
+
+	// No further datagrams arrive, so the idle watcher should close conn.
+	_, err = conn.Read(buf)
+	assert.Error(t, err)
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := tls.X509KeyPair(
+		pemBlock("CERTIFICATE", der),
+		pemBlock("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key)),
+	)
+	require.NoError(t, err)
+	return cert
+}
+
+func pemBlock(blockType string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}